@@ -0,0 +1,135 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package robustio wraps I/O functions that are prone to failure on Windows
+// and, under concurrent load, on macOS, transparently retrying errors up to
+// an arbitrary timeout.
+//
+// Errors are classified heuristically and retries are bounded, so the
+// functions in this package do not completely eliminate spurious errors.
+// However, they do significantly reduce the rate of failure in practice.
+package robustio
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+const arbitraryTimeout = 500 * time.Millisecond
+
+// Policy configures the behavior of Retry. The zero value of every field
+// selects the package's default, which matches the behavior of the
+// unexported retry loop used by Rename, ReadFile and RemoveAll.
+type Policy struct {
+	// MaxTimeout bounds the total wall-clock time Retry spends retrying.
+	MaxTimeout time.Duration
+
+	// InitialSleep is the delay before the first retry.
+	InitialSleep time.Duration
+
+	// Jitter scales the random growth applied to the sleep between
+	// successive retries; each retry sleeps up to Jitter times longer
+	// than the previous one.
+	Jitter float64
+
+	// IsEphemeral reports whether err may be resolved by waiting and
+	// retrying. A nil IsEphemeral uses the platform's default classifier,
+	// the same one Rename, ReadFile and RemoveAll use.
+	IsEphemeral func(error) bool
+}
+
+var defaultPolicy = Policy{
+	MaxTimeout:   arbitraryTimeout,
+	InitialSleep: 1 * time.Millisecond,
+	Jitter:       1,
+}
+
+// Retry retries f, which reports in mayRetry whether its error is worth
+// retrying at all (for example, callers may not want to retry a file not
+// existing), up to policy's timeout. Errors are additionally required to
+// pass policy.IsEphemeral before they are retried.
+func Retry(f func() (err error, mayRetry bool), policy Policy) error {
+	return retryContext(context.Background(), f, policy)
+}
+
+// retryContext is Retry, but returns ctx.Err() promptly if ctx is done
+// before f is next invoked or before the loop would next sleep.
+func retryContext(ctx context.Context, f func() (err error, mayRetry bool), policy Policy) error {
+	isEphemeral := policy.IsEphemeral
+	if isEphemeral == nil {
+		isEphemeral = isEphemeralError
+	}
+	maxTimeout := policy.MaxTimeout
+	if maxTimeout <= 0 {
+		maxTimeout = defaultPolicy.MaxTimeout
+	}
+	nextSleep := policy.InitialSleep
+	if nextSleep <= 0 {
+		nextSleep = defaultPolicy.InitialSleep
+	}
+	jitter := policy.Jitter
+	if jitter <= 0 {
+		jitter = defaultPolicy.Jitter
+	}
+
+	var (
+		bestErr error
+		start   time.Time
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err, mayRetry := f()
+		if err == nil || !mayRetry || !isEphemeral(err) {
+			return err
+		}
+		bestErr = err
+
+		if start.IsZero() {
+			start = time.Now()
+		} else if d := time.Since(start) + nextSleep; d >= maxTimeout {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(nextSleep):
+		}
+		bound := int64(float64(nextSleep) * jitter)
+		if bound < 1 {
+			bound = 1
+		}
+		nextSleep += time.Duration(rand.Int63n(bound))
+	}
+
+	return bestErr
+}
+
+// retry retries ephemeral errors from f up to an arbitrary timeout to work
+// around spurious filesystem errors on platforms prone to them. It is
+// Retry with the package's default Policy.
+func retry(f func() (err error, mayRetry bool)) error {
+	return Retry(f, defaultPolicy)
+}
+
+// Rename is like os.Rename, but retries ephemeral errors on platforms prone
+// to them.
+func Rename(oldpath, newpath string) error {
+	return rename(oldpath, newpath)
+}
+
+// ReadFile is like ioutil.ReadFile, but retries ephemeral errors on
+// platforms prone to them.
+func ReadFile(filename string) ([]byte, error) {
+	return readFile(filename)
+}
+
+// RemoveAll is like os.RemoveAll, but retries ephemeral errors on platforms
+// prone to them.
+func RemoveAll(path string) error {
+	return removeAll(path)
+}
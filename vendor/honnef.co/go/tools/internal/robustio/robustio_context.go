@@ -0,0 +1,42 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package robustio
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+)
+
+// RenameContext is like Rename, but returns ctx.Err() promptly if ctx is
+// done before the next retry attempt would start.
+func RenameContext(ctx context.Context, oldpath, newpath string) error {
+	return retryContext(ctx, func() (err error, mayRetry bool) {
+		err = os.Rename(oldpath, newpath)
+		return err, err != nil
+	}, defaultPolicy)
+}
+
+// ReadFileContext is like ReadFile, but returns ctx.Err() promptly if ctx
+// is done before the next retry attempt would start.
+func ReadFileContext(ctx context.Context, filename string) ([]byte, error) {
+	var b []byte
+	err := retryContext(ctx, func() (err error, mayRetry bool) {
+		b, err = ioutil.ReadFile(filename)
+
+		// As in ReadFile, do not retry a file that genuinely does not exist.
+		return err, err != nil && !os.IsNotExist(err)
+	}, defaultPolicy)
+	return b, err
+}
+
+// RemoveAllContext is like RemoveAll, but returns ctx.Err() promptly if ctx
+// is done before the next retry attempt would start.
+func RemoveAllContext(ctx context.Context, path string) error {
+	return retryContext(ctx, func() (err error, mayRetry bool) {
+		err = os.RemoveAll(path)
+		return err, err != nil
+	}, defaultPolicy)
+}
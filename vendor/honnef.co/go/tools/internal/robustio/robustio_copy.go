@@ -0,0 +1,158 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package robustio
+
+import (
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// CopyOption configures optional behavior of CopyDir.
+type CopyOption func(*copyOptions)
+
+type copyOptions struct {
+	preserveTimes bool
+}
+
+// WithPreserveTimes causes CopyDir to also preserve each copied directory's
+// modification time. Regular files copied by CopyFile always preserve
+// theirs.
+func WithPreserveTimes() CopyOption {
+	return func(o *copyOptions) { o.preserveTimes = true }
+}
+
+// CopyFile copies the file at src to dst with the given permission bits,
+// retrying ephemeral errors on platforms prone to them. The source's
+// modification time is preserved. If dst already refers to the same file
+// as src (for example, because the two paths are hardlinked together),
+// CopyFile does nothing.
+//
+// CopyFile writes to a temporary file in dst's directory and renames it
+// into place, so a concurrent reader of dst never observes a partial copy.
+func CopyFile(dst, src string, perm os.FileMode) error {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if dstInfo, err := os.Stat(dst); err == nil && os.SameFile(srcInfo, dstInfo) {
+		return nil
+	}
+
+	tmp := dst + ".tmp" + strconv.Itoa(rand.Int())
+	if err := copyFileContents(tmp, src, perm); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Chtimes(tmp, srcInfo.ModTime(), srcInfo.ModTime()); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func copyFileContents(dst, src string, perm os.FileMode) error {
+	return retry(func() (err error, mayRetry bool) {
+		err = copyFileContentsOnce(dst, src, perm)
+		return err, err != nil
+	})
+}
+
+func copyFileContentsOnce(dst, src string, perm os.FileMode) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	_, err = io.Copy(out, in)
+	return err
+}
+
+// CopyDir recursively copies the directory tree rooted at src to dst,
+// creating directories, copying regular files with CopyFile, and
+// reproducing symlinks verbatim.
+func CopyDir(dst, src string, opts ...CopyOption) error {
+	var o copyOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	type dirTime struct {
+		path    string
+		modTime time.Time
+	}
+	var dirTimes []dirTime
+
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			if err := os.MkdirAll(target, info.Mode().Perm()); err != nil {
+				return err
+			}
+			if o.preserveTimes {
+				dirTimes = append(dirTimes, dirTime{target, info.ModTime()})
+			}
+			return nil
+
+		case info.Mode()&os.ModeSymlink != 0:
+			linkTarget, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			os.Remove(target)
+			return os.Symlink(linkTarget, target)
+
+		default:
+			return CopyFile(target, path, info.Mode().Perm())
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	// Directory mtimes are bumped by writing their children, so they must
+	// be restored only after the whole subtree beneath them is copied.
+	// Walking dirTimes in reverse visits children before their parents.
+	for i := len(dirTimes) - 1; i >= 0; i-- {
+		dt := dirTimes[i]
+		if err := os.Chtimes(dt.path, dt.modTime, dt.modTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
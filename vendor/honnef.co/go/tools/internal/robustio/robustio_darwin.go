@@ -0,0 +1,64 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package robustio
+
+import (
+	"os"
+	"syscall"
+)
+
+// rename is like os.Rename, but retries ephemeral errors.
+//
+// macOS 10.14+ has been observed to return spurious ENOENT from os.Rename
+// under concurrent load (e.g. a fuzzing manager and a VM both touching the
+// workdir), so we retry ENOENT and EACCES, the counterparts of the
+// not-found and access-denied errors retried on Windows.
+func rename(oldpath, newpath string) (err error) {
+	return retry(func() (err error, mayRetry bool) {
+		err = os.Rename(oldpath, newpath)
+		return err, err != nil
+	})
+}
+
+// readFile is like os.ReadFile, but retries ephemeral errors.
+func readFile(filename string) ([]byte, error) {
+	var b []byte
+	err := retry(func() (err error, mayRetry bool) {
+		b, err = os.ReadFile(filename)
+
+		// Unlike in rename, we do not retry ENOENT here: it can occur as a
+		// spurious error, but the file may also genuinely not exist, so the
+		// increase in robustness is probably not worth the extra latency.
+
+		return err, err != nil && !os.IsNotExist(err)
+	})
+	return b, err
+}
+
+func removeAll(path string) error {
+	return retry(func() (err error, mayRetry bool) {
+		err = os.RemoveAll(path)
+		return err, err != nil
+	})
+}
+
+// isEphemeralError returns true if err may be resolved by waiting.
+func isEphemeralError(err error) bool {
+	switch werr := err.(type) {
+	case *os.PathError:
+		err = werr.Err
+	case *os.LinkError:
+		err = werr.Err
+	case *os.SyscallError:
+		err = werr.Err
+	}
+	if errno, ok := err.(syscall.Errno); ok {
+		switch errno {
+		case syscall.ENOENT, syscall.EACCES:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows && !darwin && !plan9
+
+package robustio
+
+import (
+	"os"
+)
+
+// Platforms other than Windows, macOS and Plan 9 are not known to be prone
+// to the spurious filesystem errors that this package works around, so on
+// those platforms these functions skip the retry loop entirely.
+
+func rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func readFile(filename string) ([]byte, error) {
+	return os.ReadFile(filename)
+}
+
+func removeAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+func isEphemeralError(err error) bool {
+	return false
+}
@@ -0,0 +1,77 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package robustio
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// plan9EphemeralSubstrings lists substrings of Plan 9's textual I/O errors
+// that are known to be transient under concurrent rename/remove, such as a
+// file being briefly exclusive-locked by another process.
+var plan9EphemeralSubstrings = []string{
+	"exclusive lock",
+	"lock is held",
+	"file already locked",
+}
+
+// rename is like os.Rename, but retries ephemeral errors.
+//
+// Plan 9 has no cross-directory rename, so callers renaming across
+// directories will still see os.Rename's usual error in that case; this
+// only retries the transient lock-contention errors above.
+func rename(oldpath, newpath string) (err error) {
+	return retry(func() (err error, mayRetry bool) {
+		err = os.Rename(oldpath, newpath)
+		return err, err != nil
+	})
+}
+
+// readFile is like ioutil.ReadFile, but retries ephemeral errors.
+func readFile(filename string) ([]byte, error) {
+	var b []byte
+	err := retry(func() (err error, mayRetry bool) {
+		b, err = ioutil.ReadFile(filename)
+
+		// As on Windows and Darwin, do not retry a file that genuinely
+		// does not exist.
+		return err, err != nil && !os.IsNotExist(err)
+	})
+	return b, err
+}
+
+func removeAll(path string) error {
+	return retry(func() (err error, mayRetry bool) {
+		err = os.RemoveAll(path)
+		return err, err != nil
+	})
+}
+
+// isEphemeralError returns true if err may be resolved by waiting.
+//
+// Plan 9 does not represent most I/O errors as syscall.Errno the way
+// Windows and Darwin do: os.Rename returns a *os.LinkError and most other
+// os functions a *os.PathError, either wrapping a plain string error from
+// the kernel, so ephemeral conditions are recognized by matching known
+// substrings instead.
+func isEphemeralError(err error) bool {
+	switch werr := err.(type) {
+	case *os.PathError:
+		err = werr.Err
+	case *os.LinkError:
+		err = werr.Err
+	default:
+		return false
+	}
+	msg := err.Error()
+	for _, s := range plan9EphemeralSubstrings {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
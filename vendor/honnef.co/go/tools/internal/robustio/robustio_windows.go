@@ -5,51 +5,14 @@
 package robustio
 
 import (
+	"errors"
 	"io/ioutil"
-	"math/rand"
 	"os"
 	"syscall"
-	"time"
 )
 
-const arbitraryTimeout = 500 * time.Millisecond
-
 const ERROR_SHARING_VIOLATION = 32
 
-// retry retries ephemeral errors from f up to an arbitrary timeout
-// to work around spurious filesystem errors on Windows
-func retry(f func() (err error, mayRetry bool)) error {
-	var (
-		bestErr     error
-		lowestErrno syscall.Errno
-		start       time.Time
-		nextSleep   time.Duration = 1 * time.Millisecond
-	)
-	for {
-		err, mayRetry := f()
-		if err == nil || !mayRetry {
-			return err
-		}
-
-		if errno, ok := err.(syscall.Errno); ok && (lowestErrno == 0 || errno < lowestErrno) {
-			bestErr = err
-			lowestErrno = errno
-		} else if bestErr == nil {
-			bestErr = err
-		}
-
-		if start.IsZero() {
-			start = time.Now()
-		} else if d := time.Since(start) + nextSleep; d >= arbitraryTimeout {
-			break
-		}
-		time.Sleep(nextSleep)
-		nextSleep += time.Duration(rand.Int63n(int64(nextSleep)))
-	}
-
-	return bestErr
-}
-
 // rename is like os.Rename, but retries ephemeral errors.
 //
 // It wraps os.Rename, which (as of 2019-06-04) uses MoveFileEx with
@@ -64,7 +27,7 @@ func retry(f func() (err error, mayRetry bool)) error {
 func rename(oldpath, newpath string) (err error) {
 	return retry(func() (err error, mayRetry bool) {
 		err = os.Rename(oldpath, newpath)
-		return err, isEphemeralError(err)
+		return err, err != nil
 	})
 }
 
@@ -78,7 +41,7 @@ func readFile(filename string) ([]byte, error) {
 		// as a spurious error, but the file may also genuinely not exist, so the
 		// increase in robustness is probably not worth the extra latency.
 
-		return err, isEphemeralError(err) && err != syscall.ERROR_FILE_NOT_FOUND
+		return err, err != nil && !errors.Is(err, syscall.ERROR_FILE_NOT_FOUND)
 	})
 	return b, err
 }
@@ -86,7 +49,7 @@ func readFile(filename string) ([]byte, error) {
 func removeAll(path string) error {
 	return retry(func() (err error, mayRetry bool) {
 		err = os.RemoveAll(path)
-		return err, isEphemeralError(err)
+		return err, err != nil
 	})
 }
 
@@ -109,4 +72,4 @@ func isEphemeralError(err error) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
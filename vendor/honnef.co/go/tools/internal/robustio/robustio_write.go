@@ -0,0 +1,77 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package robustio
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// WriteFile atomically writes data to filename: it writes to a sibling
+// temporary file in the same directory, fsyncs it, renames it into place
+// with Rename, and finally fsyncs the parent directory on POSIX so the
+// rename is durable across a crash. This is the standard renameio pattern,
+// and unlike ioutil.WriteFile it never leaves a truncated file behind if
+// the process is killed mid-write.
+func WriteFile(filename string, data []byte, perm os.FileMode) error {
+	return WriteFileT(filename, bytes.NewReader(data), int64(len(data)), perm)
+}
+
+// WriteFileT is WriteFile, but streams from r instead of requiring the
+// whole file in memory; size is a hint at r's length, used to preallocate
+// the temporary file, and need not be exact. It is meant for writing large
+// files, such as VM images, without buffering them twice.
+func WriteFileT(filename string, r io.Reader, size int64, perm os.FileMode) (err error) {
+	dir := filepath.Dir(filename)
+	tmp := filepath.Join(dir, "."+filepath.Base(filename)+".tmp"+strconv.Itoa(rand.Int()))
+
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp)
+		}
+	}()
+
+	if size > 0 {
+		if err = f.Truncate(size); err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	var n int64
+	if n, err = io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if n < size {
+		// The preallocation above over-shot the actual data; drop the
+		// trailing zeroes so the file doesn't grow beyond what was written.
+		if err = f.Truncate(n); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	if err = Rename(tmp, filename); err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}